@@ -0,0 +1,86 @@
+package blockchain
+
+import "sync"
+
+// Rough, fixed byte sizes used to size fees the same way a Bitcoin-style
+// wallet would: a P2PKH input and output are each approximately this many
+// bytes once serialized.
+const (
+	TXIN_SIZE  = 148
+	TXOUT_SIZE = 34
+)
+
+// FeeEstimator suggests the fee rate, in the smallest unit of value per
+// byte, that a new transaction on shardID should pay.
+type FeeEstimator interface {
+	FeeRate(shardID uint32) int
+}
+
+// StaticFeeRate always suggests the same fee rate regardless of shard or
+// recent activity. It is the default estimator.
+type StaticFeeRate struct {
+	RatePerByte int
+}
+
+// FeeRate implements FeeEstimator.
+func (s StaticFeeRate) FeeRate(shardID uint32) int {
+	return s.RatePerByte
+}
+
+// EstimateFee returns the fee, in the smallest unit of value, for a
+// transaction with numInputs inputs and numOutputs outputs paying feeRate
+// per byte.
+func EstimateFee(numInputs, numOutputs, feeRate int) int {
+	return (numInputs*TXIN_SIZE + numOutputs*TXOUT_SIZE) * feeRate
+}
+
+// MovingAverageFeeEstimator tracks the fee rate paid by the most recent
+// blocks included on each shard and suggests their average, so the
+// suggested rate adapts to recent demand instead of staying fixed.
+type MovingAverageFeeEstimator struct {
+	mu       sync.Mutex
+	window   int
+	fallback int
+	samples  map[uint32][]int
+}
+
+// NewMovingAverageFeeEstimator returns an estimator that averages the last
+// window fee-rate observations per shard, falling back to fallback when a
+// shard has no observations yet.
+func NewMovingAverageFeeEstimator(window, fallback int) *MovingAverageFeeEstimator {
+	return &MovingAverageFeeEstimator{
+		window:   window,
+		fallback: fallback,
+		samples:  make(map[uint32][]int),
+	}
+}
+
+// Observe records the fee rate paid by a block that was just included on
+// shardID, evicting the oldest sample once window is exceeded.
+func (m *MovingAverageFeeEstimator) Observe(shardID uint32, feeRate int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	samples := append(m.samples[shardID], feeRate)
+	if len(samples) > m.window {
+		samples = samples[len(samples)-m.window:]
+	}
+	m.samples[shardID] = samples
+}
+
+// FeeRate implements FeeEstimator.
+func (m *MovingAverageFeeEstimator) FeeRate(shardID uint32) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	samples := m.samples[shardID]
+	if len(samples) == 0 {
+		return m.fallback
+	}
+
+	sum := 0
+	for _, s := range samples {
+		sum += s
+	}
+	return sum / len(samples)
+}
@@ -0,0 +1,24 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"log"
+)
+
+// hashTransaction gob-encodes tx (with its ID zeroed) and returns the
+// sha256 of the result, following the standard UTXO-tutorial pattern of
+// hashing a trimmed copy of the transaction.
+func hashTransaction(tx *Transaction) [32]byte {
+	txCopy := *tx
+	txCopy.ID = [32]byte{}
+
+	var encoded bytes.Buffer
+	enc := gob.NewEncoder(&encoded)
+	if err := enc.Encode(txCopy); err != nil {
+		log.Panic(err)
+	}
+
+	return sha256.Sum256(encoded.Bytes())
+}
@@ -0,0 +1,184 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/big"
+)
+
+// TXInput references a previous transaction's output that is being spent as
+// an input of a new transaction. PubKey and Signature authenticate the
+// spend: PubKey must hash to the referenced output's PubKeyHash, and
+// Signature must be a valid signature by the matching private key over the
+// transaction.
+type TXInput struct {
+	TxID          [32]byte
+	TxOutputIndex int
+	Address       string
+	ShardID       uint32
+	PubKey        []byte
+	Signature     []byte
+}
+
+// UsesKey reports whether this input was signed by the keypair whose public
+// key hashes to pubKeyHash.
+func (in *TXInput) UsesKey(pubKeyHash []byte) bool {
+	return bytes.Equal(hashPubKey(in.PubKey), pubKeyHash)
+}
+
+// TXOutput represents a single spendable value locked to whoever holds the
+// private key matching PubKeyHash.
+type TXOutput struct {
+	Value      int
+	PubKeyHash []byte
+	ShardID    uint32
+}
+
+// Lock sets PubKeyHash from address, a hex-encoded public key hash as
+// produced by wallet.Wallet.Address.
+func (out *TXOutput) Lock(address string) error {
+	pubKeyHash, err := hex.DecodeString(address)
+	if err != nil {
+		return err
+	}
+	out.PubKeyHash = pubKeyHash
+	return nil
+}
+
+// IsLockedWithKey reports whether this output can be spent by the keypair
+// whose public key hashes to pubKeyHash.
+func (out *TXOutput) IsLockedWithKey(pubKeyHash []byte) bool {
+	return bytes.Equal(out.PubKeyHash, pubKeyHash)
+}
+
+// hashPubKey is the same hashing scheme as wallet.HashPubKey; duplicated
+// here (rather than imported) to avoid a dependency cycle between
+// blockchain and wallet.
+func hashPubKey(pubKey []byte) []byte {
+	hash := sha256.Sum256(pubKey)
+	return hash[:]
+}
+
+// curveFieldSize returns the fixed byte width of a coordinate or scalar on
+// curve (e.g. 32 for P256). r, s, X, and Y must each be padded to this
+// width before concatenation: big.Int.Bytes() returns the minimal-width
+// encoding, so an unpadded field element can come out shorter than its
+// sibling whenever it happens to have a leading zero byte, which shifts
+// the fixed split point Verify relies on to pull them back apart.
+func curveFieldSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
+
+// padToSize left-pads b with zero bytes to size, the inverse of the
+// truncation big.Int.Bytes() performs.
+func padToSize(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(b):], b)
+	return padded
+}
+
+// Transaction is a set of inputs spent into a set of outputs, along with the
+// fee paid to the network for including it.
+type Transaction struct {
+	ID        [32]byte
+	TxInput   []TXInput
+	TxOutput  []TXOutput
+	Fee       int
+	Signature []byte
+}
+
+// SetID recomputes and sets the transaction's ID from its inputs and
+// outputs.
+func (tx *Transaction) SetID() {
+	tx.ID = hashTransaction(tx)
+}
+
+// TrimmedCopy returns a copy of tx with every input's PubKey and Signature
+// cleared, the starting point for both signing and verification.
+func (tx *Transaction) TrimmedCopy() Transaction {
+	var inputs []TXInput
+	for _, in := range tx.TxInput {
+		inputs = append(inputs, TXInput{in.TxID, in.TxOutputIndex, in.Address, in.ShardID, nil, nil})
+	}
+
+	return Transaction{tx.ID, inputs, tx.TxOutput, tx.Fee, nil}
+}
+
+// Sign signs each input of tx with privKey, following the standard UTXO
+// pattern: for each input in turn, the trimmed copy's matching input
+// borrows the referenced output's PubKeyHash as a stand-in PubKey, the
+// resulting transaction is hashed, and that hash is what gets signed.
+// prevTXs maps a hex-encoded txid to the transaction it names, and must
+// contain every transaction referenced by tx's inputs.
+func (tx *Transaction) Sign(privKey ecdsa.PrivateKey, prevTXs map[string]Transaction) error {
+	txCopy := tx.TrimmedCopy()
+
+	for i, in := range tx.TxInput {
+		prevTX, ok := prevTXs[hex.EncodeToString(in.TxID[:])]
+		if !ok {
+			return errors.New("blockchain: previous transaction not found for input")
+		}
+
+		txCopy.TxInput[i].PubKey = prevTX.TxOutput[in.TxOutputIndex].PubKeyHash
+		txCopy.SetID()
+		txCopy.TxInput[i].PubKey = nil
+
+		r, s, err := ecdsa.Sign(rand.Reader, &privKey, txCopy.ID[:])
+		if err != nil {
+			return err
+		}
+		size := curveFieldSize(privKey.PublicKey.Curve)
+		tx.TxInput[i].Signature = append(padToSize(r.Bytes(), size), padToSize(s.Bytes(), size)...)
+		tx.TxInput[i].PubKey = append(padToSize(privKey.PublicKey.X.Bytes(), size), padToSize(privKey.PublicKey.Y.Bytes(), size)...)
+	}
+
+	return nil
+}
+
+// Verify checks the signature on every input of tx against the PubKeyHash
+// of the output it references. prevTXs must contain every transaction
+// referenced by tx's inputs, as for Sign.
+func (tx *Transaction) Verify(prevTXs map[string]Transaction) bool {
+	txCopy := tx.TrimmedCopy()
+	curve := elliptic.P256()
+
+	for i, in := range tx.TxInput {
+		prevTX, ok := prevTXs[hex.EncodeToString(in.TxID[:])]
+		if !ok {
+			return false
+		}
+		if !bytes.Equal(hashPubKey(in.PubKey), prevTX.TxOutput[in.TxOutputIndex].PubKeyHash) {
+			return false
+		}
+
+		txCopy.TxInput[i].PubKey = prevTX.TxOutput[in.TxOutputIndex].PubKeyHash
+		txCopy.SetID()
+		txCopy.TxInput[i].PubKey = nil
+
+		size := curveFieldSize(curve)
+		if len(in.PubKey) != 2*size || len(in.Signature) != 2*size {
+			return false
+		}
+
+		x := new(big.Int).SetBytes(in.PubKey[:size])
+		y := new(big.Int).SetBytes(in.PubKey[size:])
+		pubKey := ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+
+		r := new(big.Int).SetBytes(in.Signature[:size])
+		s := new(big.Int).SetBytes(in.Signature[size:])
+
+		if !ecdsa.Verify(&pubKey, txCopy.ID[:], r, s) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,66 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"sync"
+)
+
+// UTXOPool holds, for a single shard, every address's unspent outputs,
+// indexed as address -> hex-encoded txid -> output index -> value.
+type UTXOPool struct {
+	mu      sync.Mutex
+	UtxoMap map[string]map[string]map[int]int
+}
+
+// VerifyAndUpdate checks that tx's signatures are valid for the outputs it
+// spends, and if so removes those outputs from the pool and adds tx's own
+// outputs. It returns false, leaving the pool untouched, if tx fails
+// verification. prevTXs must contain every transaction tx's inputs
+// reference; the node keeps these alongside the flattened UtxoMap
+// specifically to support this check.
+func (pool *UTXOPool) VerifyAndUpdate(tx *Transaction, prevTXs map[string]Transaction) bool {
+	if !tx.Verify(prevTXs) {
+		return false
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	for _, in := range tx.TxInput {
+		txIDStr := hex.EncodeToString(in.TxID[:])
+		if outputs, ok := pool.UtxoMap[in.Address][txIDStr]; ok {
+			delete(outputs, in.TxOutputIndex)
+		}
+	}
+
+	txIDStr := hex.EncodeToString(tx.ID[:])
+	for i, out := range tx.TxOutput {
+		address := hex.EncodeToString(out.PubKeyHash)
+		if pool.UtxoMap[address] == nil {
+			pool.UtxoMap[address] = make(map[string]map[int]int)
+		}
+		if pool.UtxoMap[address][txIDStr] == nil {
+			pool.UtxoMap[address][txIDStr] = make(map[int]int)
+		}
+		pool.UtxoMap[address][txIDStr][i] = out.Value
+	}
+
+	return true
+}
+
+// NumUTXOs returns the total number of unspent outputs currently in the
+// pool, across every address. Callers that just want a count should use
+// this instead of ranging over UtxoMap directly, since UtxoMap is mutated
+// concurrently by VerifyAndUpdate.
+func (pool *UTXOPool) NumUTXOs() int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	count := 0
+	for _, byTx := range pool.UtxoMap {
+		for _, byIndex := range byTx {
+			count += len(byIndex)
+		}
+	}
+	return count
+}
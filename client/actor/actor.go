@@ -0,0 +1,214 @@
+package actor
+
+import (
+	"encoding/hex"
+	"harmony-benchmark/blockchain"
+	"harmony-benchmark/utxoindex"
+	"harmony-benchmark/wallet"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Actor is a long-lived simulated user. It owns a fixed subset of the
+// addresses in its shard's UTXO index and repeatedly drives its own Profile
+// to decide when and how much to spend, instead of a single global loop
+// sweeping every address in the pool on every tick.
+type Actor struct {
+	ID        int
+	ShardID   uint32
+	Addresses []string
+	Profile   Profile
+
+	indexes      []*utxoindex.Index
+	indexMu      *sync.Mutex
+	feeEstimator blockchain.FeeEstimator
+	keystore     *wallet.Keystore
+	destinations []string
+	out          chan<- *blockchain.Transaction
+	crossOut     chan<- *blockchain.Transaction
+	override     *atomic.Value
+}
+
+// newActor constructs an Actor that owns addrs on shardID and publishes the
+// transactions it generates onto out (single-shard) and crossOut
+// (cross-shard). indexes holds one utxoindex.Index per shard; keystore
+// holds the wallet (and therefore the signing key) for every known address,
+// including addrs and destinations. override is the Simulation's shared
+// profile override (see Simulation.SetProfile); it is consulted on every
+// tick so an operator can repin every actor to a single profile mid-run.
+func newActor(id int, shardID uint32, addrs []string, profile Profile, indexes []*utxoindex.Index, indexMu *sync.Mutex, feeEstimator blockchain.FeeEstimator, keystore *wallet.Keystore, destinations []string, out, crossOut chan<- *blockchain.Transaction, override *atomic.Value) *Actor {
+	return &Actor{
+		ID:           id,
+		ShardID:      shardID,
+		Addresses:    addrs,
+		Profile:      profile,
+		indexes:      indexes,
+		indexMu:      indexMu,
+		feeEstimator: feeEstimator,
+		keystore:     keystore,
+		destinations: destinations,
+		out:          out,
+		crossOut:     crossOut,
+		override:     override,
+	}
+}
+
+// currentProfile returns the Simulation-wide override profile if one has
+// been set via SetProfile, and the actor's own Profile otherwise.
+func (a *Actor) currentProfile() Profile {
+	if h, ok := a.override.Load().(profileHolder); ok && h.profile != nil {
+		return h.profile
+	}
+	return a.Profile
+}
+
+// Run drives the actor's current profile until stop is closed, generating
+// transactions from the actor's own addresses on every tick.
+func (a *Actor) Run(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		profile := a.currentProfile()
+		for i := 0; i < profile.BatchSize(); i++ {
+			a.generateOne(profile.CrossShard())
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(profile.Interval()):
+		}
+	}
+}
+
+// generateOne selects enough UTXOs owned by the actor to cover a randomly
+// chosen send amount plus the estimated fee, optionally pairing them with a
+// second selection from a neighboring shard, signs the result with the
+// spending address's key, and publishes the transaction (with any leftover
+// value returned as a change output) on the actor's output channel.
+func (a *Actor) generateOne(crossShard bool) {
+	if len(a.Addresses) == 0 || len(a.destinations) == 0 {
+		return
+	}
+	address := a.Addresses[rand.Intn(len(a.Addresses))]
+	signer, ok := a.keystore.GetWallet(address)
+	if !ok {
+		return
+	}
+	sendAmount := 1 + rand.Intn(100)
+
+	a.indexMu.Lock()
+	selected, total, ok := selectForSpend(a.indexes[a.ShardID], address, sendAmount, a.feeEstimator.FeeRate(a.ShardID))
+	a.indexMu.Unlock()
+	if !ok {
+		return
+	}
+
+	txInputs := make([]blockchain.TXInput, 0, len(selected))
+	for _, u := range selected {
+		txInputs = append(txInputs, blockchain.TXInput{u.TxID, u.Index, address, a.ShardID, nil, nil})
+	}
+	txOutputs := []blockchain.TXOutput{a.payTo(a.randomDestination(), sendAmount, a.ShardID)}
+
+	dest := a.out
+	if crossShard {
+		crossShardID := (a.ShardID + 1) % uint32(len(a.indexes))
+		crossSendAmount := 1 + rand.Intn(100)
+
+		a.indexMu.Lock()
+		crossSelected, crossTotal, crossOk := selectForSpend(a.indexes[crossShardID], address, crossSendAmount, a.feeEstimator.FeeRate(crossShardID))
+		a.indexMu.Unlock()
+
+		if crossOk {
+			for _, u := range crossSelected {
+				txInputs = append(txInputs, blockchain.TXInput{u.TxID, u.Index, address, crossShardID, nil, nil})
+			}
+			txOutputs = append(txOutputs, a.payTo(a.randomDestination(), crossSendAmount, crossShardID))
+			dest = a.crossOut
+
+			if change := crossTotal - crossSendAmount; change > 0 {
+				txOutputs = append(txOutputs, a.payTo(address, change, crossShardID))
+			}
+		}
+	}
+
+	fee := blockchain.EstimateFee(len(txInputs), len(txOutputs), a.feeEstimator.FeeRate(a.ShardID))
+	if total < sendAmount+fee {
+		return
+	}
+	if change := total - sendAmount - fee; change > 0 {
+		txOutputs = append(txOutputs, a.payTo(address, change, a.ShardID))
+	}
+
+	tx := blockchain.Transaction{[32]byte{}, txInputs, txOutputs, fee, nil}
+	tx.SetID()
+
+	if err := tx.Sign(signer.PrivateKey, prevTXsFor(txInputs, signer.PublicKey)); err != nil {
+		return
+	}
+	dest <- &tx
+}
+
+// payTo builds an output locking value to address on shardID.
+func (a *Actor) payTo(address string, value int, shardID uint32) blockchain.TXOutput {
+	out := blockchain.TXOutput{Value: value, ShardID: shardID}
+	out.Lock(address)
+	return out
+}
+
+// randomDestination picks an arbitrary known address to receive a payment.
+func (a *Actor) randomDestination() string {
+	return a.destinations[rand.Intn(len(a.destinations))]
+}
+
+// prevTXsFor synthesizes the minimal prevTXs map Transaction.Sign needs: for
+// each input, a transaction whose output at the spent index is locked with
+// ownerPubKey's hash, since that is the only thing Sign actually reads.
+func prevTXsFor(inputs []blockchain.TXInput, ownerPubKey []byte) map[string]blockchain.Transaction {
+	pubKeyHash := wallet.HashPubKey(ownerPubKey)
+	prevTXs := make(map[string]blockchain.Transaction, len(inputs))
+
+	for _, in := range inputs {
+		key := hex.EncodeToString(in.TxID[:])
+		prevTX, ok := prevTXs[key]
+		if !ok {
+			prevTX = blockchain.Transaction{TxOutput: make([]blockchain.TXOutput, in.TxOutputIndex+1)}
+		}
+		for len(prevTX.TxOutput) <= in.TxOutputIndex {
+			prevTX.TxOutput = append(prevTX.TxOutput, blockchain.TXOutput{})
+		}
+		prevTX.TxOutput[in.TxOutputIndex] = blockchain.TXOutput{PubKeyHash: pubKeyHash}
+		prevTXs[key] = prevTX
+	}
+
+	return prevTXs
+}
+
+// selectForSpend draws UTXOs for address from idx until their total value
+// covers need plus the estimated fee for the inputs drawn so far. ok is
+// false if the address's indexed UTXOs can't cover the amount.
+func selectForSpend(idx *utxoindex.Index, address string, need int, feeRate int) (selected []utxoindex.UTXO, total int, ok bool) {
+	// maxCount is a generous upper bound: selection stops as soon as the
+	// running total covers need+fee, so this only bounds the pathological
+	// case of many small UTXOs.
+	const maxCount = 64
+	for _, u := range idx.SelectUTXOsForAddress(address, 1, maxCount) {
+		selected = append(selected, u)
+		total += u.Value
+
+		// Assume a single output here; the caller adds more outputs (a
+		// second shard's output, change) after selection, which raises
+		// the required fee, so this check is only a lower bound and
+		// generateOne checks the final fee again.
+		if total >= need+blockchain.EstimateFee(len(selected), 1, feeRate) {
+			return selected, total, true
+		}
+	}
+	return nil, 0, false
+}
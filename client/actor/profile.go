@@ -0,0 +1,113 @@
+package actor
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Profile controls how an Actor behaves: how often it wakes up, how many
+// transactions it tries to generate per tick, and how likely each of those
+// transactions is to spend across shards. Concrete profiles let a benchmark
+// run reproduce a specific mix of user behavior (e.g. mostly small spenders
+// with a handful of whales) instead of the single uniform rate the old
+// generator produced.
+type Profile interface {
+	// Interval returns how long the actor should sleep between ticks.
+	Interval() time.Duration
+	// BatchSize returns how many transactions to attempt on this tick.
+	BatchSize() int
+	// CrossShard reports whether the next transaction in the batch should
+	// spend a UTXO from a second shard in addition to the owning shard.
+	CrossShard() bool
+}
+
+// SteadySpender generates a small, constant trickle of single-shard
+// transactions. This is the default, low-intensity profile.
+type SteadySpender struct{}
+
+func (SteadySpender) Interval() time.Duration { return 500 * time.Millisecond }
+func (SteadySpender) BatchSize() int          { return 1 + rand.Intn(3) }
+func (SteadySpender) CrossShard() bool        { return false }
+
+// Whale occasionally moves much larger batches than a SteadySpender, with a
+// small chance of reaching across shards.
+type Whale struct{}
+
+func (Whale) Interval() time.Duration { return 2 * time.Second }
+func (Whale) BatchSize() int          { return 20 + rand.Intn(30) }
+func (Whale) CrossShard() bool        { return rand.Intn(100) < 15 }
+
+// Burst idles most of the time and then fires a large spike of
+// transactions, simulating a flash of activity such as an airdrop claim.
+type Burst struct{}
+
+func (Burst) Interval() time.Duration {
+	if rand.Intn(10) == 0 {
+		return 50 * time.Millisecond
+	}
+	return 5 * time.Second
+}
+func (Burst) BatchSize() int {
+	if rand.Intn(10) == 0 {
+		return 50 + rand.Intn(100)
+	}
+	return 0
+}
+func (Burst) CrossShard() bool { return false }
+
+// CrossShardHeavy behaves like SteadySpender but strongly prefers
+// transactions that span two shards, stressing the cross-shard proof path.
+type CrossShardHeavy struct{}
+
+func (CrossShardHeavy) Interval() time.Duration { return 500 * time.Millisecond }
+func (CrossShardHeavy) BatchSize() int          { return 1 + rand.Intn(3) }
+func (CrossShardHeavy) CrossShard() bool        { return rand.Intn(100) < 90 }
+
+// weightedProfile pairs a Profile with its selection weight for DefaultMix.
+type weightedProfile struct {
+	profile Profile
+	weight  int
+}
+
+// DefaultMix approximates a realistic population: mostly steady spenders
+// with a minority of whales, bursts, and cross-shard-heavy actors.
+var DefaultMix = []weightedProfile{
+	{SteadySpender{}, 70},
+	{Whale{}, 10},
+	{Burst{}, 10},
+	{CrossShardHeavy{}, 10},
+}
+
+// profileByName looks up one of the named profiles, for callers (e.g. the
+// RPC control plane) that let an operator pin every actor to a single
+// profile instead of drawing from DefaultMix.
+func profileByName(name string) (Profile, bool) {
+	switch name {
+	case "steady_spender":
+		return SteadySpender{}, true
+	case "whale":
+		return Whale{}, true
+	case "burst":
+		return Burst{}, true
+	case "cross_shard_heavy":
+		return CrossShardHeavy{}, true
+	default:
+		return nil, false
+	}
+}
+
+// pickProfile draws a Profile from mix according to its weights.
+func pickProfile(mix []weightedProfile) Profile {
+	total := 0
+	for _, w := range mix {
+		total += w.weight
+	}
+	r := rand.Intn(total)
+	for _, w := range mix {
+		if r < w.weight {
+			return w.profile
+		}
+		r -= w.weight
+	}
+	return mix[len(mix)-1].profile
+}
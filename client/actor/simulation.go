@@ -0,0 +1,191 @@
+// Package actor implements the actor-driven transaction generator used by
+// txgen. Each Actor is a long-lived goroutine that owns a subset of a
+// shard's addresses and drives its own send rate and cross-shard preference
+// via a Profile, replacing the single monolithic generation loop that used
+// to sweep the entire UTXO pool under one global lock on every tick.
+package actor
+
+import (
+	"harmony-benchmark/blockchain"
+	"harmony-benchmark/node"
+	"harmony-benchmark/utxoindex"
+	"harmony-benchmark/wallet"
+	"sync"
+	"sync/atomic"
+)
+
+// profileHolder wraps a Profile so Simulation.override (an atomic.Value)
+// always stores the same concrete type, even when there is no override:
+// atomic.Value panics if successive Store calls don't agree on type, and a
+// bare nil Profile interface can't be told apart from "never stored".
+type profileHolder struct {
+	profile Profile
+}
+
+// Simulation spawns and supervises the actors for a benchmark run. Each
+// shard gets its own Outbound channel so a sender loop can route a shard's
+// transactions to that shard's leader; CrossOutbound carries transactions
+// that span two shards and must be broadcast to every leader.
+type Simulation struct {
+	// Outbound[i] receives single-shard transactions generated by actors
+	// belonging to shard i.
+	Outbound []chan *blockchain.Transaction
+	// CrossOutbound receives cross-shard transactions from all actors.
+	CrossOutbound chan *blockchain.Transaction
+
+	dataNodes    []*node.Node
+	indexMu      sync.Mutex
+	indexes      []*utxoindex.Index
+	feeEstimator blockchain.FeeEstimator
+	keystore     *wallet.Keystore
+	actors       []*Actor
+	override     atomic.Value
+
+	runMu   sync.Mutex
+	running bool
+	stop    chan struct{}
+}
+
+// NewSimulation creates a Simulation over dataNodes (one entry per shard),
+// spawning actorsPerShard actors per shard once Start is called. Each
+// shard's UTXOs are loaded once into a utxoindex.Index, which actors then
+// sample from instead of scanning dataNodes[i].UtxoPool.UtxoMap directly.
+// Actors draw their profile from DefaultMix and size fees using a
+// MovingAverageFeeEstimator fed by Observe as blocks come in.
+//
+// Every address discovered across all shards gets a generated wallet in
+// keystorePath so actors can sign the transactions they send; this bridges
+// until node.AddTestingAddresses mints and persists real wallets itself.
+func NewSimulation(dataNodes []*node.Node, actorsPerShard int, keystorePath string) *Simulation {
+	s := &Simulation{
+		CrossOutbound: make(chan *blockchain.Transaction, 4096),
+		dataNodes:     dataNodes,
+		feeEstimator:  blockchain.NewMovingAverageFeeEstimator(50, 1),
+		keystore:      wallet.NewKeystore(keystorePath),
+	}
+
+	perShardAddrs := make([][]string, len(dataNodes))
+	var allAddrs []string
+	for shardID := range dataNodes {
+		idx := utxoindex.New(utxoindex.NewMemStore())
+		utxoindex.Load(idx, uint32(shardID), dataNodes[shardID].UtxoPool.UtxoMap)
+		s.indexes = append(s.indexes, idx)
+
+		s.Outbound = append(s.Outbound, make(chan *blockchain.Transaction, 4096))
+
+		addrs := idx.Addresses()
+		perShardAddrs[shardID] = addrs
+		allAddrs = append(allAddrs, addrs...)
+	}
+
+	for _, addr := range allAddrs {
+		if _, ok := s.keystore.GetWallet(addr); !ok {
+			s.keystore.Import(addr, wallet.NewWallet())
+		}
+	}
+	s.keystore.SaveToFile()
+
+	for shardID, addrs := range perShardAddrs {
+		shares := partition(addrs, actorsPerShard)
+		for i := 0; i < actorsPerShard; i++ {
+			a := newActor(i, uint32(shardID), shares[i], pickProfile(DefaultMix), s.indexes, &s.indexMu, s.feeEstimator, s.keystore, allAddrs, s.Outbound[shardID], s.CrossOutbound, &s.override)
+			s.actors = append(s.actors, a)
+		}
+	}
+
+	return s
+}
+
+// Observe feeds the fee rate paid by a block just included on shardID into
+// the simulation's fee estimator, so subsequent actor transactions track
+// recent demand on that shard.
+func (s *Simulation) Observe(shardID uint32, feeRate int) {
+	if m, ok := s.feeEstimator.(*blockchain.MovingAverageFeeEstimator); ok {
+		m.Observe(shardID, feeRate)
+	}
+}
+
+// Start spawns one goroutine per actor, unless the simulation is already
+// running. Call Stop to bring them all down; Start may be called again
+// afterwards to resume, which is what lets generator.start/generator.stop
+// restart load without relaunching the process.
+func (s *Simulation) Start() {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	if s.running {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.running = true
+	for _, a := range s.actors {
+		go a.Run(s.stop)
+	}
+}
+
+// Stop signals every actor to exit; it does not close Outbound/CrossOutbound
+// since a sender loop may still be draining them.
+func (s *Simulation) Stop() {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	if !s.running {
+		return
+	}
+	close(s.stop)
+	s.running = false
+}
+
+// SetProfile pins every actor to the named profile, overriding whatever
+// profile each actor was assigned from DefaultMix at construction time.
+// Passing an empty name clears the override, returning actors to their own
+// assigned profile. It reports false if name isn't a known profile.
+func (s *Simulation) SetProfile(name string) bool {
+	if name == "" {
+		s.override.Store(profileHolder{})
+		return true
+	}
+	p, ok := profileByName(name)
+	if !ok {
+		return false
+	}
+	s.override.Store(profileHolder{p})
+	return true
+}
+
+// Lock acquires the lock shared by every actor reading or writing the
+// simulation's UTXO indexes. Callers that apply a block outside of an actor
+// must hold this lock while calling ApplyBlock.
+func (s *Simulation) Lock() { s.indexMu.Lock() }
+
+// Unlock releases the lock acquired by Lock.
+func (s *Simulation) Unlock() { s.indexMu.Unlock() }
+
+// ApplyBlock updates shardID's index with the effects of txs, keeping the
+// index consistent as new blocks arrive. Callers must hold Lock.
+func (s *Simulation) ApplyBlock(shardID uint32, txs []*blockchain.Transaction) {
+	utxoindex.ApplyBlock(s.indexes[shardID], shardID, txs)
+}
+
+// partition splits addrs into n roughly-even, non-overlapping shares so
+// that no two actors contend for the same address.
+func partition(addrs []string, n int) [][]string {
+	shares := make([][]string, n)
+	for i, addr := range addrs {
+		shares[i%n] = append(shares[i%n], addr)
+	}
+	return shares
+}
+
+// Drain collects up to max pending transactions from ch without blocking,
+// for a sender loop to batch into a single outbound message.
+func Drain(ch chan *blockchain.Transaction, max int) []*blockchain.Transaction {
+	var txs []*blockchain.Transaction
+	for len(txs) < max {
+		select {
+		case tx := <-ch:
+			txs = append(txs, tx)
+		default:
+			return txs
+		}
+	}
+	return txs
+}
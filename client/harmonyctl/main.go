@@ -0,0 +1,69 @@
+// Command harmonyctl is a small client for the JSON-RPC control plane the
+// rpc package exposes on txgen and on each node.Node (analogous to
+// bytomcli), so an operator can script a benchmark scenario (ramp, spike,
+// cross-shard burst) by calling RPC methods instead of relaunching
+// processes with different flags.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:9000", "host:port of the JSON-RPC server to call")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "usage: harmonyctl -addr host:port <method> [paramsJSON]")
+		os.Exit(1)
+	}
+
+	if err := call(*addr, args[0], paramsArg(args)); err != nil {
+		fmt.Fprintln(os.Stderr, "harmonyctl:", err)
+		os.Exit(1)
+	}
+}
+
+// paramsArg returns the raw JSON params argument, if one was given.
+func paramsArg(args []string) json.RawMessage {
+	if len(args) < 2 {
+		return nil
+	}
+	return json.RawMessage(args[1])
+}
+
+// call sends method(params) to the JSON-RPC server at addr and prints its
+// response.
+func call(addr, method string, params json.RawMessage) error {
+	req := struct {
+		JSONRPC string          `json:"jsonrpc"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params,omitempty"`
+		ID      int             `json:"id"`
+	}{JSONRPC: "2.0", Method: method, Params: params, ID: 1}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("encoding request: %v", err)
+	}
+
+	resp, err := http.Post(fmt.Sprintf("http://%s/", addr), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	out, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %v", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
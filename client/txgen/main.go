@@ -7,157 +7,19 @@ import (
 	"fmt"
 	"harmony-benchmark/blockchain"
 	"harmony-benchmark/client"
+	"harmony-benchmark/client/actor"
 	"harmony-benchmark/consensus"
 	"harmony-benchmark/log"
+	"harmony-benchmark/mempool"
 	"harmony-benchmark/node"
 	"harmony-benchmark/p2p"
-	"math/rand"
+	"harmony-benchmark/rpc"
 	"os"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 )
 
-var utxoPoolMutex sync.Mutex
-
-// Generates at most "maxNumTxs" number of simulated transactions based on the current UtxoPools of all shards.
-// The transactions are generated by going through the existing utxos and
-// randomly select a subset of them as the input for each new transaction. The output
-// address of the new transaction are randomly selected from [0 - N), where N is the total number of fake addresses.
-//
-// When crossShard=true, besides the selected utxo input, select another valid utxo as input from the same address in a second shard.
-// Similarly, generate another utxo output in that second shard.
-//
-// NOTE: the genesis block should contain N coinbase transactions which add
-//       token (1000) to each address in [0 - N). See node.AddTestingAddresses()
-//
-// Params:
-//     shardId                    - the shardId for current shard
-//     dataNodes                  - nodes containing utxopools of all shards
-//     maxNumTxs                  - the max number of txs to generate
-//     crossShard                 - whether to generate cross shard txs
-// Returns:
-//     all single-shard txs
-//     all cross-shard txs
-func generateSimulatedTransactions(shardId int, dataNodes []*node.Node, maxNumTxs int, crossShard bool) ([]*blockchain.Transaction, []*blockchain.Transaction) {
-	/*
-	  UTXO map structure:
-	     address - [
-	                txId1 - [
-	                        outputIndex1 - value1
-	                        outputIndex2 - value2
-	                       ]
-	                txId2 - [
-	                        outputIndex1 - value1
-	                        outputIndex2 - value2
-	                       ]
-	               ]
-	*/
-	var txs []*blockchain.Transaction
-	var crossTxs []*blockchain.Transaction
-	txsCount := 0
-
-	utxoPoolMutex.Lock()
-
-UTXOLOOP:
-	// Loop over all addresses
-	for address, txMap := range dataNodes[shardId].UtxoPool.UtxoMap {
-		// Loop over all txIds for the address
-		for txIdStr, utxoMap := range txMap {
-			// Parse TxId
-			id, err := hex.DecodeString(txIdStr)
-			if err != nil {
-				continue
-			}
-			txId := [32]byte{}
-			copy(txId[:], id[:])
-
-			// Loop over all utxos for the txId
-			for index, value := range utxoMap {
-				if txsCount >= maxNumTxs {
-					break UTXOLOOP
-				}
-				randNum := rand.Intn(100)
-
-				// 30% sample rate to select UTXO to use for new transactions
-				if randNum < 30 {
-					if crossShard && randNum < 10 { // 30% cross shard transactions: add another txinput from another shard
-						// shard with neighboring Id
-						crossShardId := (int(dataNodes[shardId].Consensus.ShardID) + 1) % len(dataNodes)
-
-						crossShardNode := dataNodes[crossShardId]
-						crossShardUtxosMap := crossShardNode.UtxoPool.UtxoMap[address]
-
-						// Get the cross shard utxo from another shard
-						var crossTxin *blockchain.TXInput
-						crossUtxoValue := 0
-						// Loop over utxos for the same address from the other shard and use the first utxo as the second cross tx input
-						for crossTxIdStr, crossShardUtxos := range crossShardUtxosMap {
-							// Parse TxId
-							id, err := hex.DecodeString(crossTxIdStr)
-							if err != nil {
-								continue
-							}
-							crossTxId := [32]byte{}
-							copy(crossTxId[:], id[:])
-
-							for crossShardIndex, crossShardValue := range crossShardUtxos {
-								crossUtxoValue = crossShardValue
-								crossTxin = &blockchain.TXInput{crossTxId, crossShardIndex, address, uint32(crossShardId)}
-								break
-							}
-							if crossTxin != nil {
-								break
-							}
-						}
-
-						// Add the utxo from current shard
-						txin := blockchain.TXInput{txId, index, address, dataNodes[shardId].Consensus.ShardID}
-						txInputs := []blockchain.TXInput{txin}
-
-						// Add the utxo from the other shard, if any
-						if crossTxin != nil {
-							txInputs = append(txInputs, *crossTxin)
-						}
-
-						// Spend the utxo from the current shard to a random address in [0 - N)
-						txout := blockchain.TXOutput{value, strconv.Itoa(rand.Intn(10000)), dataNodes[shardId].Consensus.ShardID}
-						txOutputs := []blockchain.TXOutput{txout}
-
-						// Spend the utxo from the other shard, if any, to a random address in [0 - N)
-						if crossTxin != nil {
-							crossTxout := blockchain.TXOutput{crossUtxoValue, strconv.Itoa(rand.Intn(10000)), uint32(crossShardId)}
-							txOutputs = append(txOutputs, crossTxout)
-						}
-
-						// Construct the new transaction
-						tx := blockchain.Transaction{[32]byte{}, txInputs, txOutputs, nil}
-						tx.SetID()
-
-						crossTxs = append(crossTxs, &tx)
-						txsCount++
-					} else {
-						// Add the utxo as new tx input
-						txin := blockchain.TXInput{txId, index, address, dataNodes[shardId].Consensus.ShardID}
-
-						// Spend the utxo to a random address in [0 - N)
-						txout := blockchain.TXOutput{value, strconv.Itoa(rand.Intn(10000)), dataNodes[shardId].Consensus.ShardID}
-						tx := blockchain.Transaction{[32]byte{}, []blockchain.TXInput{txin}, []blockchain.TXOutput{txout}, nil}
-						tx.SetID()
-
-						txs = append(txs, &tx)
-						txsCount++
-					}
-				}
-			}
-		}
-	}
-	utxoPoolMutex.Unlock()
-
-	return txs, crossTxs
-}
-
 // Gets all the validator peers
 func getValidators(config string) []p2p.Peer {
 	file, _ := os.Open(config)
@@ -242,17 +104,16 @@ func countNumOfUtxos(utxoPool *blockchain.UTXOPool) int {
 
 func main() {
 	configFile := flag.String("config_file", "local_config.txt", "file containing all ip addresses and config")
-	maxNumTxsPerBatch := flag.Int("max_num_txs_per_batch", 100000, "number of transactions to send per message")
+	actorsPerShard := flag.Int("actors_per_shard", 32, "number of simulated actors driving traffic on each shard")
 	logFolder := flag.String("log_folder", "latest", "the folder collecting the logs of this execution")
+	keystoreFile := flag.String("keystore_file", "txgen_keystore.dat", "file holding the generated wallets actors sign transactions with")
+	rpcPort := flag.String("rpc_port", "9000", "port the JSON-RPC control plane (generator/node/cluster) listens on")
 	flag.Parse()
 
 	// Read the configs
 	config := readConfigFile(*configFile)
 	leaders, shardIds := getLeadersAndShardIds(&config)
 
-	// Do cross shard tx if there are more than one shard
-	crossShard := len(shardIds) > 1
-
 	// TODO(Richard): refactor this chuck to a single method
 	// Setup a logger to stdout and log file.
 	logFileName := fmt.Sprintf("./%v/txgen.log", *logFolder)
@@ -277,6 +138,10 @@ func main() {
 	consensusObj := consensus.NewConsensus("0", clientPort, "0", nil, p2p.Peer{})
 	clientNode := node.New(consensusObj)
 
+	// sim owns the population of simulated user actors and the UTXO pool
+	// lock they share with block updates below.
+	sim := actor.NewSimulation(nodes, *actorsPerShard, *keystoreFile)
+
 	if clientPort != "" {
 		clientNode.Client = client.NewClient(&leaders)
 
@@ -287,10 +152,23 @@ func main() {
 				for _, node := range nodes {
 					if node.Consensus.ShardID == block.ShardId {
 						log.Debug("Adding block from leader", "shardId", block.ShardId)
-						// Add it to blockchain
-						utxoPoolMutex.Lock()
+						// Add it to blockchain, then keep the actor simulation's
+						// UTXO index consistent with the newly applied block.
+						sim.Lock()
 						node.AddNewBlock(block)
-						utxoPoolMutex.Unlock()
+						sim.ApplyBlock(block.ShardId, block.Transactions)
+						sim.Unlock()
+
+						// Feed the block's average fee rate to the actors'
+						// fee estimator.
+						fee, size := 0, 0
+						for _, tx := range block.Transactions {
+							fee += tx.Fee
+							size += blockchain.EstimateFee(len(tx.TxInput), len(tx.TxOutput), 1)
+						}
+						if size > 0 {
+							sim.Observe(block.ShardId, fee/size)
+						}
 					} else {
 						continue
 					}
@@ -299,6 +177,15 @@ func main() {
 		}
 		clientNode.Client.UpdateBlocks = updateBlocksFunc
 
+		// Leaders report why they rejected transactions from this run so
+		// per-reason counts show up in the log instead of throughput numbers
+		// silently absorbing dropped transactions.
+		clientNode.Client.UpdateRejections = func(shardId uint32, rejected map[mempool.RejectReason]int) {
+			for reason, count := range rejected {
+				log.Debug("[Generator] Leader rejected transactions", "shardId", shardId, "reason", reason, "count", count)
+			}
+		}
+
 		// Start the client server to listen to leader's message
 		go func() {
 			clientNode.StartServer(clientPort)
@@ -306,50 +193,35 @@ func main() {
 
 	}
 
-	// Transaction generation process
-	time.Sleep(10 * time.Second) // wait for nodes to be ready
-	start := time.Now()
-	totalTime := 300.0 //run for 5 minutes
+	// Per-shard node.* RPC bindings mirror each shard's UTXO pool; txgen
+	// doesn't run a real mempool or keep block history of its own, so those
+	// methods report an explicit error rather than fabricating data.
+	var nodeServices []*rpc.NodeService
+	for i, n := range nodes {
+		nodeServices = append(nodeServices, rpc.NewNodeService(shardIds[i], n.UtxoPool, nil, nil, nil))
+	}
 
-	for true {
-		t := time.Now()
-		if t.Sub(start).Seconds() >= totalTime {
-			log.Debug("Generator timer ended.", "duration", (int(t.Sub(start))), "startTime", start, "totalTime", totalTime)
-			break
-		}
+	generator := rpc.NewGenerator(sim, leaders, clientNode)
+	done := make(chan struct{})
 
-		allCrossTxs := []*blockchain.Transaction{}
-		// Generate simulated transactions
-		for i, leader := range leaders {
-			txs, crossTxs := generateSimulatedTransactions(i, nodes, *maxNumTxsPerBatch, crossShard)
-			allCrossTxs = append(allCrossTxs, crossTxs...)
+	rpcServer := rpc.NewServer()
+	generator.Register(rpcServer)
+	rpc.NewMultiNodeService(nodeServices).Register(rpcServer)
+	rpc.NewClusterService(append(getValidators(*configFile), leaders...), func() { close(done) }).Register(rpcServer)
 
-			log.Debug("[Generator] Sending single-shard txs ...", "leader", leader, "numTxs", len(txs), "numCrossTxs", len(crossTxs))
-			msg := node.ConstructTransactionListMessage(txs)
-			p2p.SendMessage(leader, msg)
-			// Note cross shard txs are later sent in batch
+	rpcAddr := "0.0.0.0:" + *rpcPort
+	go func() {
+		log.Info("[Generator] JSON-RPC control plane listening", "addr", rpcAddr)
+		if err := rpc.ListenAndServe(rpcAddr, rpcServer); err != nil {
+			log.Error("[Generator] JSON-RPC server stopped", "err", err)
 		}
+	}()
 
-		if len(allCrossTxs) > 0 {
-			log.Debug("[Generator] Broadcasting cross-shard txs ...", "allCrossTxs", len(allCrossTxs))
-			msg := node.ConstructTransactionListMessage(allCrossTxs)
-			p2p.BroadcastMessage(leaders, msg)
-
-			// Put cross shard tx into a pending list waiting for proofs from leaders
-			if clientPort != "" {
-				clientNode.Client.PendingCrossTxsMutex.Lock()
-				for _, tx := range allCrossTxs {
-					clientNode.Client.PendingCrossTxs[tx.ID] = tx
-				}
-				clientNode.Client.PendingCrossTxsMutex.Unlock()
-			}
-		}
-
-		time.Sleep(500 * time.Millisecond) // Send a batch of transactions periodically
-	}
-
-	// Send a stop message to stop the nodes at the end
-	msg := node.ConstructStopMessage()
-	peers := append(getValidators(*configFile), leaders...)
-	p2p.BroadcastMessage(peers, msg)
+	// Transaction generation is now driven entirely by generator.start,
+	// generator.stop, and generator.setProfile over the RPC control plane
+	// above, so an operator can script a benchmark scenario (ramp, spike,
+	// cross-shard burst) from a driver process without relaunching txgen
+	// with different flags. This blocks until cluster.stopAll is called.
+	time.Sleep(10 * time.Second) // wait for nodes to be ready
+	<-done
 }
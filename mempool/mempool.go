@@ -0,0 +1,251 @@
+// Package mempool holds the transactions a leader has accepted but not yet
+// included in a block, plus a record of why any rejected transaction was
+// turned away. Without the rejection record, transactions that silently
+// fail admission (wrong shard, bad signature, a fee too low to clear the
+// pool) are indistinguishable from ones lost to network flakiness, which
+// makes benchmark throughput numbers impossible to interpret.
+package mempool
+
+import (
+	"harmony-benchmark/blockchain"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RejectReason explains why a transaction was refused admission.
+type RejectReason string
+
+const (
+	ReasonDoubleSpend RejectReason = "double_spend"
+	ReasonBadSig      RejectReason = "bad_signature"
+	ReasonWrongShard  RejectReason = "wrong_shard"
+	ReasonFeeTooLow   RejectReason = "fee_too_low"
+	ReasonPoolFull    RejectReason = "pool_full"
+	ReasonRateLimited RejectReason = "rate_limited"
+)
+
+// RejectedTx records a single rejection for later inspection.
+type RejectedTx struct {
+	Reason RejectReason
+	Time   time.Time
+	Size   int
+}
+
+// Stats summarizes the current state of a Mempool.
+type Stats struct {
+	Pending  int
+	Rejected map[RejectReason]int
+}
+
+// Mempool holds one shard's pending and recently-rejected transactions.
+type Mempool struct {
+	mu         sync.Mutex
+	shardID    uint32
+	maxPending int
+
+	Pending    map[[32]byte]*blockchain.Transaction
+	admittedAt map[[32]byte]time.Time // Pending tx admission times, for evictOne's age term.
+
+	// Rejected is capped at maxPending entries, oldest first in
+	// rejectedOrder, so a long benchmark run's rejection history can't grow
+	// without bound.
+	Rejected      map[[32]byte]*RejectedTx
+	rejectedOrder [][32]byte
+
+	limiter *rateLimiter
+}
+
+// NewMempool returns an empty Mempool for shardID that admits at most
+// maxPending transactions at once, evicting the worst by size*age/feerate
+// once full, retains at most maxPending recent rejections, and rate-limits
+// each source to ratePerSource admissions per second.
+func NewMempool(shardID uint32, maxPending int, ratePerSource int) *Mempool {
+	return &Mempool{
+		shardID:    shardID,
+		maxPending: maxPending,
+		Pending:    make(map[[32]byte]*blockchain.Transaction),
+		admittedAt: make(map[[32]byte]time.Time),
+		Rejected:   make(map[[32]byte]*RejectedTx),
+		limiter:    newRateLimiter(ratePerSource, time.Second),
+	}
+}
+
+// Accept validates tx and, if it passes, admits it to Pending. source
+// identifies where tx arrived from (e.g. the sending peer), for the
+// per-source rate limiter.
+func (m *Mempool) Accept(tx *blockchain.Transaction, source string, minFeeRate int, prevTXs map[string]blockchain.Transaction) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.Pending[tx.ID]; ok {
+		return true // already admitted
+	}
+	if !m.limiter.Allow(source) {
+		m.reject(tx.ID, ReasonRateLimited, tx)
+		return false
+	}
+	if _, conflicted := m.conflicts(tx); conflicted {
+		m.reject(tx.ID, ReasonDoubleSpend, tx)
+		return false
+	}
+	for _, in := range tx.TxInput {
+		if in.ShardID != m.shardID {
+			m.reject(tx.ID, ReasonWrongShard, tx)
+			return false
+		}
+	}
+	if !tx.Verify(prevTXs) {
+		m.reject(tx.ID, ReasonBadSig, tx)
+		return false
+	}
+	if feeRate(tx) < minFeeRate {
+		m.reject(tx.ID, ReasonFeeTooLow, tx)
+		return false
+	}
+
+	if len(m.Pending) >= m.maxPending {
+		m.evictOne()
+	}
+
+	m.Pending[tx.ID] = tx
+	m.admittedAt[tx.ID] = time.Now()
+	return true
+}
+
+// Reject explicitly rejects id, recording reason. Used when a transaction
+// is disqualified by something outside of Accept's own checks (e.g.
+// consensus discovering a conflict with an already-included block).
+func (m *Mempool) Reject(id [32]byte, reason RejectReason) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removePending(id)
+	m.recordRejection(id, &RejectedTx{Reason: reason, Time: time.Now()})
+}
+
+// reject records a rejection for tx; callers must hold m.mu.
+func (m *Mempool) reject(id [32]byte, reason RejectReason, tx *blockchain.Transaction) {
+	m.recordRejection(id, &RejectedTx{Reason: reason, Time: time.Now(), Size: txSize(tx)})
+}
+
+// recordRejection adds rejected to Rejected, evicting the oldest recorded
+// rejection if that would push Rejected past maxPending entries; callers
+// must hold m.mu.
+func (m *Mempool) recordRejection(id [32]byte, rejected *RejectedTx) {
+	if _, exists := m.Rejected[id]; !exists {
+		m.rejectedOrder = append(m.rejectedOrder, id)
+	}
+	m.Rejected[id] = rejected
+
+	for len(m.rejectedOrder) > m.maxPending {
+		oldest := m.rejectedOrder[0]
+		m.rejectedOrder = m.rejectedOrder[1:]
+		delete(m.Rejected, oldest)
+	}
+}
+
+// Remove drops ids from Pending, e.g. because they were just included in a
+// block (or conflict with one that was).
+func (m *Mempool) Remove(ids ...[32]byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		m.removePending(id)
+	}
+}
+
+// removePending deletes id from Pending and admittedAt; callers must hold
+// m.mu.
+func (m *Mempool) removePending(id [32]byte) {
+	delete(m.Pending, id)
+	delete(m.admittedAt, id)
+}
+
+// TopN returns up to n pending transactions ordered by descending feerate,
+// for consensus to draw block proposals from.
+func (m *Mempool) TopN(n int) []*blockchain.Transaction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	txs := make([]*blockchain.Transaction, 0, len(m.Pending))
+	for _, tx := range m.Pending {
+		txs = append(txs, tx)
+	}
+	sort.Slice(txs, func(i, j int) bool { return feeRate(txs[i]) > feeRate(txs[j]) })
+
+	if len(txs) > n {
+		txs = txs[:n]
+	}
+	return txs
+}
+
+// Stats summarizes the mempool's current pending count and rejection
+// counts by reason.
+func (m *Mempool) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	byReason := make(map[RejectReason]int)
+	for _, r := range m.Rejected {
+		byReason[r.Reason]++
+	}
+	return Stats{Pending: len(m.Pending), Rejected: byReason}
+}
+
+// conflicts reports whether tx spends an input already spent by a pending
+// transaction, and if so returns that transaction's id.
+func (m *Mempool) conflicts(tx *blockchain.Transaction) ([32]byte, bool) {
+	for _, in := range tx.TxInput {
+		for id, pending := range m.Pending {
+			for _, pendingIn := range pending.TxInput {
+				if pendingIn.TxID == in.TxID && pendingIn.TxOutputIndex == in.TxOutputIndex {
+					return id, true
+				}
+			}
+		}
+	}
+	return [32]byte{}, false
+}
+
+// evictOne drops the pending transaction with the highest size*age/feerate
+// score - the largest, stalest, cheapest one - to make room for a new one;
+// callers must hold m.mu. Scores are computed fresh on every call rather
+// than maintained incrementally, since age changes continuously; this is
+// O(len(Pending)), the same cost Accept already pays via conflicts.
+func (m *Mempool) evictOne() {
+	var victim [32]byte
+	var victimTx *blockchain.Transaction
+	worstScore := -1.0
+	now := time.Now()
+
+	for id, tx := range m.Pending {
+		age := now.Sub(m.admittedAt[id]).Seconds()
+		score := float64(txSize(tx)) * age / float64(feeRate(tx)+1)
+		if score > worstScore {
+			worstScore = score
+			victim, victimTx = id, tx
+		}
+	}
+
+	if victimTx == nil {
+		return
+	}
+	m.removePending(victim)
+	m.recordRejection(victim, &RejectedTx{Reason: ReasonPoolFull, Time: now, Size: txSize(victimTx)})
+}
+
+// feeRate returns tx's fee per byte, using the same byte-size model as
+// blockchain.EstimateFee.
+func feeRate(tx *blockchain.Transaction) int {
+	size := txSize(tx)
+	if size == 0 {
+		return 0
+	}
+	return tx.Fee / size
+}
+
+// txSize estimates tx's serialized size the same way blockchain.EstimateFee
+// does: a fixed cost per input and per output.
+func txSize(tx *blockchain.Transaction) int {
+	return len(tx.TxInput)*blockchain.TXIN_SIZE + len(tx.TxOutput)*blockchain.TXOUT_SIZE
+}
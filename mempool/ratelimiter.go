@@ -0,0 +1,51 @@
+package mempool
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter enforces a maximum number of admissions per source within a
+// sliding window, so a single noisy source can't crowd out everyone else's
+// transactions.
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	seen   map[string][]time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, seen: make(map[string][]time.Time)}
+}
+
+// Allow reports whether source may admit another transaction right now.
+// Only allowed attempts are recorded and count against later calls; a
+// denied attempt is not. A limit of zero or less disables limiting.
+func (r *rateLimiter) Allow(source string) bool {
+	if r.limit <= 0 {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-r.window)
+
+	times := r.seen[source]
+	kept := times[:0]
+	for _, t := range times {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+
+	if len(kept) >= r.limit {
+		r.seen[source] = kept
+		return false
+	}
+
+	r.seen[source] = append(kept, now)
+	return true
+}
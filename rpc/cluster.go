@@ -0,0 +1,43 @@
+package rpc
+
+import (
+	"encoding/json"
+	"harmony-benchmark/node"
+	"harmony-benchmark/p2p"
+	"sync"
+)
+
+// ClusterService exposes cluster-wide control, currently just the ability
+// to broadcast a stop message to every known peer the way txgen's main
+// loop used to do unconditionally at the end of its hard-coded timer.
+type ClusterService struct {
+	peers  []p2p.Peer
+	onStop func()
+
+	stopOnce sync.Once
+}
+
+// NewClusterService returns a ClusterService that stops every peer in
+// peers. onStop, if non-nil, is called after the broadcast so the calling
+// process (e.g. txgen) can shut itself down too; pass nil if the caller has
+// nothing to do afterwards. onStop fires at most once no matter how many
+// times stopAll is called, so a caller that e.g. closes a channel in onStop
+// doesn't have to guard against a second cluster.stopAll panicking on a
+// double close.
+func NewClusterService(peers []p2p.Peer, onStop func()) *ClusterService {
+	return &ClusterService{peers: peers, onStop: onStop}
+}
+
+func (c *ClusterService) stopAll(params json.RawMessage) (interface{}, error) {
+	p2p.BroadcastMessage(c.peers, node.ConstructStopMessage())
+	if c.onStop != nil {
+		c.stopOnce.Do(c.onStop)
+	}
+	return "stopped", nil
+}
+
+// Register adds this ClusterService's methods to s under the cluster.*
+// namespace.
+func (c *ClusterService) Register(s *Server) {
+	s.Register("cluster.stopAll", c.stopAll)
+}
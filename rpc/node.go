@@ -0,0 +1,168 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"harmony-benchmark/blockchain"
+	"harmony-benchmark/mempool"
+	"harmony-benchmark/p2p"
+)
+
+// BlockSource looks up a block by height, e.g. a node's local blockchain.
+type BlockSource func(height int) (*blockchain.Block, bool)
+
+// PeerSource returns the peers a node currently knows about.
+type PeerSource func() []p2p.Peer
+
+// NodeService exposes one shard's UTXO pool, mempool, blockchain, and peer
+// list over JSON-RPC, so a driver process can inspect benchmark state
+// without tailing logs. mp, blocks, and peers may be nil for a process
+// (such as txgen, which only mirrors UTXO state) that doesn't track them.
+type NodeService struct {
+	shardID  uint32
+	utxoPool *blockchain.UTXOPool
+	mempool  *mempool.Mempool
+	blocks   BlockSource
+	peers    PeerSource
+}
+
+// NewNodeService returns a NodeService for shardID.
+func NewNodeService(shardID uint32, utxoPool *blockchain.UTXOPool, mp *mempool.Mempool, blocks BlockSource, peers PeerSource) *NodeService {
+	return &NodeService{shardID: shardID, utxoPool: utxoPool, mempool: mp, blocks: blocks, peers: peers}
+}
+
+// UTXOStats is the result of node.getUTXOStats.
+type UTXOStats struct {
+	ShardID  uint32 `json:"shardId"`
+	NumUTXOs int    `json:"numUtxos"`
+}
+
+func (n *NodeService) getUTXOStats(params json.RawMessage) (interface{}, error) {
+	return UTXOStats{ShardID: n.shardID, NumUTXOs: n.utxoPool.NumUTXOs()}, nil
+}
+
+// MempoolSummary is the result of node.getMempool: the mempool's current
+// stats plus up to its "limit" param of highest-feerate pending
+// transactions.
+type MempoolSummary struct {
+	Stats mempool.Stats              `json:"stats"`
+	Top   []*blockchain.Transaction `json:"top"`
+}
+
+func (n *NodeService) getMempool(params json.RawMessage) (interface{}, error) {
+	if n.mempool == nil {
+		return nil, fmt.Errorf("node has no mempool")
+	}
+	var p struct {
+		Limit int `json:"limit"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+	}
+	if p.Limit <= 0 {
+		p.Limit = 20
+	}
+	return MempoolSummary{Stats: n.mempool.Stats(), Top: n.mempool.TopN(p.Limit)}, nil
+}
+
+func (n *NodeService) getBlock(params json.RawMessage) (interface{}, error) {
+	if n.blocks == nil {
+		return nil, fmt.Errorf("node has no block history")
+	}
+	var p struct {
+		Height int `json:"height"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	block, ok := n.blocks(p.Height)
+	if !ok {
+		return nil, fmt.Errorf("no block at height %d", p.Height)
+	}
+	return block, nil
+}
+
+func (n *NodeService) getPeers(params json.RawMessage) (interface{}, error) {
+	if n.peers == nil {
+		return nil, fmt.Errorf("node has no peer list")
+	}
+	return n.peers(), nil
+}
+
+// Register adds this NodeService's methods to s under the node.*
+// namespace, for a process (e.g. a single node.Node) that tracks exactly
+// one shard. Processes tracking several shards, like txgen, should use
+// MultiNodeService instead.
+func (n *NodeService) Register(s *Server) {
+	s.Register("node.getUTXOStats", n.getUTXOStats)
+	s.Register("node.getMempool", n.getMempool)
+	s.Register("node.getBlock", n.getBlock)
+	s.Register("node.getPeers", n.getPeers)
+}
+
+// MultiNodeService multiplexes node.* methods across several NodeServices
+// by shardId param, for a process (such as txgen) that mirrors more than
+// one shard's state instead of running one node.Node per shard.
+type MultiNodeService struct {
+	byShard map[uint32]*NodeService
+}
+
+// NewMultiNodeService indexes services by their shard ID.
+func NewMultiNodeService(services []*NodeService) *MultiNodeService {
+	m := &MultiNodeService{byShard: make(map[uint32]*NodeService)}
+	for _, svc := range services {
+		m.byShard[svc.shardID] = svc
+	}
+	return m
+}
+
+func (m *MultiNodeService) lookup(params json.RawMessage) (*NodeService, error) {
+	var p struct {
+		ShardID uint32 `json:"shardId"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+	}
+	svc, ok := m.byShard[p.ShardID]
+	if !ok {
+		return nil, fmt.Errorf("no node tracked for shard %d", p.ShardID)
+	}
+	return svc, nil
+}
+
+// Register adds this MultiNodeService's methods to s under the node.*
+// namespace.
+func (m *MultiNodeService) Register(s *Server) {
+	s.Register("node.getUTXOStats", func(params json.RawMessage) (interface{}, error) {
+		svc, err := m.lookup(params)
+		if err != nil {
+			return nil, err
+		}
+		return svc.getUTXOStats(params)
+	})
+	s.Register("node.getMempool", func(params json.RawMessage) (interface{}, error) {
+		svc, err := m.lookup(params)
+		if err != nil {
+			return nil, err
+		}
+		return svc.getMempool(params)
+	})
+	s.Register("node.getBlock", func(params json.RawMessage) (interface{}, error) {
+		svc, err := m.lookup(params)
+		if err != nil {
+			return nil, err
+		}
+		return svc.getBlock(params)
+	})
+	s.Register("node.getPeers", func(params json.RawMessage) (interface{}, error) {
+		svc, err := m.lookup(params)
+		if err != nil {
+			return nil, err
+		}
+		return svc.getPeers(params)
+	})
+}
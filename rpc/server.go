@@ -0,0 +1,113 @@
+// Package rpc implements a minimal JSON-RPC 2.0 control plane used to drive
+// and inspect a benchmark run from an external process, instead of baking a
+// fixed run duration and batch size into txgen's command-line flags. Each
+// control surface (the transaction generator, a node's local state, the
+// cluster as a whole) registers its methods onto a shared Server.
+package rpc
+
+import (
+	"encoding/json"
+	"harmony-benchmark/log"
+	"net/http"
+)
+
+// Request is a JSON-RPC 2.0 request object.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// Response is a JSON-RPC 2.0 response object; exactly one of Result and
+// Error is set.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// Error is a JSON-RPC 2.0 error object.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Handler serves a single JSON-RPC method. It unmarshals params itself
+// (typically into a small struct tagged for encoding/json) and returns
+// either a JSON-marshalable result or an error describing why it couldn't.
+type Handler func(params json.RawMessage) (interface{}, error)
+
+// Server dispatches JSON-RPC 2.0 requests received over HTTP to Handlers
+// registered under a method name, e.g. "generator.start" or
+// "node.getUTXOStats".
+type Server struct {
+	methods map[string]Handler
+}
+
+// NewServer returns a Server with no methods registered.
+func NewServer() *Server {
+	return &Server{methods: make(map[string]Handler)}
+}
+
+// Register adds name to the dispatch table. It panics if name is already
+// registered, since that would silently shadow the earlier registration.
+func (s *Server) Register(name string, h Handler) {
+	if _, exists := s.methods[name]; exists {
+		panic("rpc: method already registered: " + name)
+	}
+	s.methods[name] = h
+}
+
+// ServeHTTP implements http.Handler, decoding a single JSON-RPC request per
+// POST body and writing back its JSON-RPC response.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, nil, codeParseError, "parse error: "+err.Error())
+		return
+	}
+
+	h, ok := s.methods[req.Method]
+	if !ok {
+		writeError(w, req.ID, codeMethodNotFound, "method not found: "+req.Method)
+		return
+	}
+
+	result, err := h(req.Params)
+	if err != nil {
+		writeError(w, req.ID, codeInvalidParams, err.Error())
+		return
+	}
+
+	writeResult(w, req.ID, result)
+}
+
+func writeResult(w http.ResponseWriter, id json.RawMessage, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	resp := Response{JSONRPC: "2.0", Result: result, ID: id}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Error("[rpc] Failed to encode response", "err", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, id json.RawMessage, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	resp := Response{JSONRPC: "2.0", Error: &Error{Code: code, Message: message}, ID: id}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Error("[rpc] Failed to encode error response", "err", err)
+	}
+}
+
+// ListenAndServe starts s on addr. It blocks like http.ListenAndServe, so
+// callers typically run it in a goroutine.
+func ListenAndServe(addr string, s *Server) error {
+	return http.ListenAndServe(addr, s)
+}
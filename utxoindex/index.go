@@ -0,0 +1,143 @@
+// Package utxoindex maintains per-address secondary indexes over a shard's
+// UTXO set so callers can select an address's UTXOs in roughly O(k) time,
+// where k is the number of UTXOs actually needed, instead of scanning the
+// full nested address/txid/index map under a single pool-wide lock.
+// Sampling across a whole shard (RandomUTXOs) is not O(k): it walks every
+// UTXO in the store.
+package utxoindex
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// Outpoint identifies a single transaction output.
+type Outpoint struct {
+	TxID  [32]byte
+	Index int
+}
+
+// UTXO is a single unspent output together with the data needed to spend
+// it: which address owns it, which shard it lives on, and its value.
+type UTXO struct {
+	Outpoint
+	Address string
+	ShardID uint32
+	Value   int
+}
+
+// Index is a secondary index over one shard's UTXO set, keyed by address.
+// It is safe for concurrent use.
+type Index struct {
+	mu        sync.RWMutex
+	store     Store
+	byAddress map[string]map[Outpoint]struct{}
+	pin       uint64
+}
+
+// New returns an empty Index backed by store. Pass NewMemStore() for a
+// process-local index. Store is defined as an interface so a disk-backed
+// implementation (e.g. one wrapping BoltDB) could plug in later, but no
+// such implementation ships yet, and nothing in this package persists or
+// reloads Pin across a restart on its own — see Pin's doc comment.
+func New(store Store) *Index {
+	return &Index{
+		store:     store,
+		byAddress: make(map[string]map[Outpoint]struct{}),
+	}
+}
+
+// Add records utxo as unspent and available for selection.
+func (idx *Index) Add(utxo UTXO) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.byAddress[utxo.Address] == nil {
+		idx.byAddress[utxo.Address] = make(map[Outpoint]struct{})
+	}
+	idx.byAddress[utxo.Address][utxo.Outpoint] = struct{}{}
+	idx.store.Put(utxo)
+}
+
+// Remove marks the outpoint owned by address as spent.
+func (idx *Index) Remove(address string, out Outpoint) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	delete(idx.byAddress[address], out)
+	idx.store.Delete(out)
+}
+
+// SelectUTXOsForAddress returns UTXOs owned by address worth at least
+// minValue each, stopping as soon as maxCount have been gathered (or the
+// address's set is exhausted) rather than scanning the whole index.
+func (idx *Index) SelectUTXOsForAddress(address string, minValue int, maxCount int) []UTXO {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var selected []UTXO
+	for out := range idx.byAddress[address] {
+		utxo, ok := idx.store.Get(out)
+		if !ok || utxo.Value < minValue {
+			continue
+		}
+		selected = append(selected, utxo)
+		if len(selected) >= maxCount {
+			break
+		}
+	}
+	return selected
+}
+
+// RandomUTXOs returns up to n UTXOs sampled from shardID without regard to
+// which address owns them, for callers (e.g. txgen) that just need
+// plausible spends rather than a specific address's balance. Each call
+// copies every UTXO of shardID out of the store before sampling, so its
+// cost grows with the store's size rather than with n.
+func (idx *Index) RandomUTXOs(shardID uint32, n int) []UTXO {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	all := idx.store.All(shardID)
+	if len(all) <= n {
+		return all
+	}
+
+	rand.Shuffle(len(all), func(i, j int) { all[i], all[j] = all[j], all[i] })
+	return all[:n]
+}
+
+// Addresses returns every address with at least one indexed UTXO, for
+// callers (e.g. the actor simulation) that need to discover the index's
+// full address population up front rather than sampling it.
+func (idx *Index) Addresses() []string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	addrs := make([]string, 0, len(idx.byAddress))
+	for addr := range idx.byAddress {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// Pin returns the height of the highest block this index has fully
+// absorbed. It is a bookkeeping primitive for a future resumable-reindex
+// feature: nothing in this package calls SetPin or reads Pin back on
+// startup yet, so today a restarted process still has to rebuild the index
+// from genesis via Load. A caller wiring up its own checkpoint/restore path
+// can use Pin/SetPin as the building block for tracking progress.
+func (idx *Index) Pin() uint64 {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.pin
+}
+
+// SetPin records height as the highest block fully absorbed into the
+// index. See Pin's doc comment: nothing currently persists this value or
+// resumes from it automatically.
+func (idx *Index) SetPin(height uint64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.pin = height
+}
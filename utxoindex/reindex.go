@@ -0,0 +1,53 @@
+package utxoindex
+
+import (
+	"encoding/hex"
+	"harmony-benchmark/blockchain"
+)
+
+// ApplyBlock updates idx with the effects of a single block on shardID:
+// every spent input is removed and every new output is added. Callers
+// should invoke this from the same path that feeds blocks into
+// node.AddNewBlock so the index never falls behind the UTXO pool it
+// mirrors.
+func ApplyBlock(idx *Index, shardID uint32, txs []*blockchain.Transaction) {
+	for _, tx := range txs {
+		for _, in := range tx.TxInput {
+			idx.Remove(in.Address, Outpoint{TxID: in.TxID, Index: in.TxOutputIndex})
+		}
+		for i, out := range tx.TxOutput {
+			idx.Add(UTXO{
+				Outpoint: Outpoint{TxID: tx.ID, Index: i},
+				Address:  hex.EncodeToString(out.PubKeyHash),
+				ShardID:  out.ShardID,
+				Value:    out.Value,
+			})
+		}
+	}
+}
+
+// Load populates idx from an existing UtxoMap snapshot (address -> hex txid
+// -> output index -> value), as kept by blockchain.UTXOPool. This is used
+// once at startup to seed the index from the pool's initial state; ongoing
+// updates should go through ApplyBlock instead of re-scanning the pool.
+func Load(idx *Index, shardID uint32, utxoMap map[string]map[string]map[int]int) {
+	for address, txMap := range utxoMap {
+		for txIDStr, outputs := range txMap {
+			id, err := hex.DecodeString(txIDStr)
+			if err != nil {
+				continue
+			}
+			var txID [32]byte
+			copy(txID[:], id)
+
+			for index, value := range outputs {
+				idx.Add(UTXO{
+					Outpoint: Outpoint{TxID: txID, Index: index},
+					Address:  address,
+					ShardID:  shardID,
+					Value:    value,
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package utxoindex
+
+import "sync"
+
+// Store is the primary UTXO store keyed by outpoint. Index uses it to hold
+// the actual UTXO data while byAddress holds only the secondary lookup.
+// MemStore is the only implementation today and is lost on restart; a
+// disk-backed implementation (BoltDB, LevelDB) could satisfy the same
+// interface to survive one, but writing and wiring up that implementation
+// is still future work.
+type Store interface {
+	Get(out Outpoint) (UTXO, bool)
+	Put(utxo UTXO)
+	Delete(out Outpoint)
+	All(shardID uint32) []UTXO
+}
+
+// MemStore is an in-memory Store. It is lost on process exit.
+type MemStore struct {
+	mu   sync.RWMutex
+	data map[Outpoint]UTXO
+}
+
+// NewMemStore returns an empty in-memory Store.
+func NewMemStore() *MemStore {
+	return &MemStore{data: make(map[Outpoint]UTXO)}
+}
+
+// Get implements Store.
+func (m *MemStore) Get(out Outpoint) (UTXO, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	u, ok := m.data[out]
+	return u, ok
+}
+
+// Put implements Store.
+func (m *MemStore) Put(utxo UTXO) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[utxo.Outpoint] = utxo
+}
+
+// Delete implements Store.
+func (m *MemStore) Delete(out Outpoint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, out)
+}
+
+// All implements Store.
+func (m *MemStore) All(shardID uint32) []UTXO {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var all []UTXO
+	for _, u := range m.data {
+		if u.ShardID == shardID {
+			all = append(all, u)
+		}
+	}
+	return all
+}
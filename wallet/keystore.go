@@ -0,0 +1,138 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/gob"
+	"math/big"
+	"os"
+	"sync"
+)
+
+// bytesToBigInt is a small readability wrapper around big.Int.SetBytes.
+func bytesToBigInt(b []byte) *big.Int {
+	return new(big.Int).SetBytes(b)
+}
+
+// Keystore is an on-disk collection of wallets, keyed by address. Txgen
+// uses it to mint and persist the N wallets node.AddTestingAddresses seeds
+// the genesis block with, so a benchmark run can be restarted against the
+// same addresses.
+type Keystore struct {
+	mu      sync.Mutex
+	path    string
+	Wallets map[string]*Wallet
+}
+
+// NewKeystore returns a Keystore backed by path, loading any wallets
+// already saved there.
+func NewKeystore(path string) *Keystore {
+	ks := &Keystore{path: path, Wallets: make(map[string]*Wallet)}
+	ks.LoadFromFile()
+	return ks
+}
+
+// CreateWallet generates a new wallet, adds it to the keystore, and returns
+// its address. It does not persist the keystore; call SaveToFile when done
+// minting wallets.
+func (ks *Keystore) CreateWallet() string {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	w := NewWallet()
+	address := w.Address()
+	ks.Wallets[address] = w
+	return address
+}
+
+// Import adds an already-generated wallet under address, for callers that
+// must key a wallet by an address minted elsewhere rather than the one
+// derived from its own public key.
+func (ks *Keystore) Import(address string, w *Wallet) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.Wallets[address] = w
+}
+
+// GetWallet returns the wallet for address, if known.
+func (ks *Keystore) GetWallet(address string) (*Wallet, bool) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	w, ok := ks.Wallets[address]
+	return w, ok
+}
+
+// Addresses returns every address currently in the keystore.
+func (ks *Keystore) Addresses() []string {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	addrs := make([]string, 0, len(ks.Wallets))
+	for a := range ks.Wallets {
+		addrs = append(addrs, a)
+	}
+	return addrs
+}
+
+// gobWallet is the on-disk shape of a Wallet: ecdsa.PrivateKey doesn't
+// gob-encode directly since elliptic.Curve is an interface, so the curve is
+// dropped and reconstructed as P256 on load (the only curve NewKeyPair
+// produces).
+type gobWallet struct {
+	D, X, Y   []byte
+	PublicKey []byte
+}
+
+// SaveToFile gob-encodes the keystore's wallets to ks.path.
+func (ks *Keystore) SaveToFile() error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	encoded := make(map[string]gobWallet, len(ks.Wallets))
+	for addr, w := range ks.Wallets {
+		encoded[addr] = gobWallet{
+			D:         w.PrivateKey.D.Bytes(),
+			X:         w.PrivateKey.PublicKey.X.Bytes(),
+			Y:         w.PrivateKey.PublicKey.Y.Bytes(),
+			PublicKey: w.PublicKey,
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(encoded); err != nil {
+		return err
+	}
+	return os.WriteFile(ks.path, buf.Bytes(), 0600)
+}
+
+// LoadFromFile replaces ks.Wallets with the contents of ks.path. It is a
+// no-op if the file doesn't exist yet.
+func (ks *Keystore) LoadFromFile() error {
+	data, err := os.ReadFile(ks.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var encoded map[string]gobWallet
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&encoded); err != nil {
+		return err
+	}
+
+	curve := elliptic.P256()
+	wallets := make(map[string]*Wallet, len(encoded))
+	for addr, gw := range encoded {
+		w := &Wallet{PublicKey: gw.PublicKey}
+		w.PrivateKey.PublicKey.Curve = curve
+		w.PrivateKey.PublicKey.X = bytesToBigInt(gw.X)
+		w.PrivateKey.PublicKey.Y = bytesToBigInt(gw.Y)
+		w.PrivateKey.D = bytesToBigInt(gw.D)
+		wallets[addr] = w
+	}
+
+	ks.mu.Lock()
+	ks.Wallets = wallets
+	ks.mu.Unlock()
+	return nil
+}